@@ -0,0 +1,228 @@
+package renter
+
+import (
+	"container/list"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/persist"
+
+	"github.com/NebulousLabs/errors"
+)
+
+const (
+	cacheMetadataHeader  = "Renter Download Cache"
+	cacheMetadataVersion = "0.1"
+	cacheIndexFilename   = "cache.json"
+)
+
+// cacheKey uniquely identifies a downloaded chunk range. ContentHash is
+// derived from the file's master key (rather than its siapath or contract
+// IDs), so that a rename or a repair against a different host still hits
+// the cache. It is a one-way hash rather than the master key itself, so
+// that the persisted index never leaks file-decryption key material.
+type cacheKey struct {
+	ContentHash crypto.Hash
+	Offset      uint64
+	Length      uint64
+}
+
+// cacheEntry describes a single on-disk cache entry.
+type cacheEntry struct {
+	Key      cacheKey
+	Filename string
+	Size     uint64
+}
+
+// DownloadCache is an on-disk, LRU-evicted cache of previously-downloaded
+// chunk data. It is consulted before managedDownloadLogicalChunkData or
+// newSectionDownload dials out to any host, and is populated opportunistically
+// whenever a chunk is actually downloaded.
+type DownloadCache struct {
+	dir     string
+	maxSize uint64
+	size    uint64
+
+	// entries and lru are kept in sync: lru.Front() is the most recently
+	// used entry, and each list element's Value is a *cacheEntry whose
+	// Key also appears in entries.
+	entries map[cacheKey]*list.Element
+	lru     *list.List
+
+	mu sync.Mutex
+}
+
+// cacheIndexPersist is the on-disk representation of the cache's index file.
+type cacheIndexPersist struct {
+	Entries []cacheEntry
+}
+
+// NewDownloadCache creates (or loads) an on-disk download cache rooted at
+// dir, capped at maxSize bytes.
+func NewDownloadCache(dir string, maxSize uint64) (*DownloadCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Extend(err, errors.New("unable to create cache directory"))
+	}
+	dc := &DownloadCache{
+		dir:     dir,
+		maxSize: maxSize,
+		entries: make(map[cacheKey]*list.Element),
+		lru:     list.New(),
+	}
+
+	var index cacheIndexPersist
+	err := persist.LoadJSON(persist.Metadata{
+		Header:  cacheMetadataHeader,
+		Version: cacheMetadataVersion,
+	}, &index, filepath.Join(dir, cacheIndexFilename))
+	if os.IsNotExist(err) {
+		return dc, nil
+	} else if err != nil {
+		return nil, errors.Extend(err, errors.New("unable to load cache index"))
+	}
+	for _, entry := range index.Entries {
+		entry := entry
+		if _, err := os.Stat(filepath.Join(dir, entry.Filename)); err != nil {
+			// The backing file is missing; drop the stale entry rather than
+			// fail the whole load.
+			continue
+		}
+		elem := dc.lru.PushBack(&entry)
+		dc.entries[entry.Key] = elem
+		dc.size += entry.Size
+	}
+	return dc, nil
+}
+
+// Get returns the cached data for key, if present. Found entries are moved
+// to the front of the LRU.
+func (dc *DownloadCache) Get(key cacheKey) ([]byte, bool) {
+	dc.mu.Lock()
+	elem, ok := dc.entries[key]
+	if !ok {
+		dc.mu.Unlock()
+		return nil, false
+	}
+	dc.lru.MoveToFront(elem)
+	entry := elem.Value.(*cacheEntry)
+	dc.mu.Unlock()
+
+	data, err := os.ReadFile(filepath.Join(dc.dir, entry.Filename))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Put stores data under key, evicting the least-recently-used entries if
+// necessary to stay under maxSize.
+func (dc *DownloadCache) Put(key cacheKey, data []byte) error {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	if elem, ok := dc.entries[key]; ok {
+		dc.lru.MoveToFront(elem)
+		return nil
+	}
+	if uint64(len(data)) > dc.maxSize {
+		// Doesn't fit even in an empty cache; not an error, just a no-op.
+		return nil
+	}
+
+	filenameHash := crypto.HashObject(key)
+	filename := hex.EncodeToString(filenameHash[:]) + ".cache"
+	if err := os.WriteFile(filepath.Join(dc.dir, filename), data, 0600); err != nil {
+		return errors.Extend(err, errors.New("unable to write cache entry"))
+	}
+	entry := &cacheEntry{Key: key, Filename: filename, Size: uint64(len(data))}
+	dc.entries[key] = dc.lru.PushFront(entry)
+	dc.size += entry.Size
+
+	for dc.size > dc.maxSize {
+		dc.evictOldestLocked()
+	}
+	return dc.persistLocked()
+}
+
+// evictOldestLocked removes the least-recently-used entry. dc.mu must be
+// held.
+func (dc *DownloadCache) evictOldestLocked() {
+	elem := dc.lru.Back()
+	if elem == nil {
+		return
+	}
+	entry := elem.Value.(*cacheEntry)
+	dc.lru.Remove(elem)
+	delete(dc.entries, entry.Key)
+	dc.size -= entry.Size
+	os.Remove(filepath.Join(dc.dir, entry.Filename))
+}
+
+// Flush empties the cache, deleting all cached data from disk.
+func (dc *DownloadCache) Flush() error {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	for dc.lru.Len() > 0 {
+		dc.evictOldestLocked()
+	}
+	return dc.persistLocked()
+}
+
+// Resize changes the cache's maximum size, evicting entries if the new
+// size is smaller than the amount of data currently cached.
+func (dc *DownloadCache) Resize(maxSize uint64) error {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.maxSize = maxSize
+	for dc.size > dc.maxSize {
+		dc.evictOldestLocked()
+	}
+	return dc.persistLocked()
+}
+
+// Size returns the current and maximum size of the cache, in bytes.
+func (dc *DownloadCache) Size() (size, maxSize uint64) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	return dc.size, dc.maxSize
+}
+
+// persistLocked writes the cache index to disk. dc.mu must be held.
+func (dc *DownloadCache) persistLocked() error {
+	index := cacheIndexPersist{Entries: make([]cacheEntry, 0, len(dc.entries))}
+	for e := dc.lru.Front(); e != nil; e = e.Next() {
+		index.Entries = append(index.Entries, *e.Value.(*cacheEntry))
+	}
+	return persist.SaveJSON(persist.Metadata{
+		Header:  cacheMetadataHeader,
+		Version: cacheMetadataVersion,
+	}, index, filepath.Join(dc.dir, cacheIndexFilename))
+}
+
+// cacheKeyForChunk builds the cache key for a logical chunk download. The
+// file's master key is hashed rather than stored verbatim, since cacheKey
+// ends up persisted to disk as part of the cache index.
+func cacheKeyForChunk(chunk *unfinishedChunk) cacheKey {
+	return cacheKey{
+		ContentHash: crypto.HashObject(chunk.renterFile.masterKey),
+		Offset:      uint64(chunk.offset),
+		Length:      chunk.length,
+	}
+}
+
+// SetDownloadCache constructs an on-disk download cache rooted at dir,
+// capped at maxSize bytes, and installs it as the renter's download cache.
+// It is the entry point daemon construction calls to actually enable
+// caching; until this is called, r.cache is nil and
+// managedDownloadLogicalChunkData falls back to always dialing a host.
+func (r *Renter) SetDownloadCache(dir string, maxSize uint64) error {
+	cache, err := NewDownloadCache(dir, maxSize)
+	if err != nil {
+		return err
+	}
+	r.cache = cache
+	return nil
+}
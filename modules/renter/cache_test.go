@@ -0,0 +1,84 @@
+package renter
+
+import (
+	"os"
+	"testing"
+)
+
+func TestCachePutGetEvictPersist(t *testing.T) {
+	dir := t.TempDir()
+	dc, err := NewDownloadCache(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k1 := cacheKey{Offset: 0, Length: 5}
+	k2 := cacheKey{Offset: 5, Length: 5}
+	k3 := cacheKey{Offset: 10, Length: 5}
+
+	if err := dc.Put(k1, []byte("aaaaa")); err != nil {
+		t.Fatal(err)
+	}
+	if err := dc.Put(k2, []byte("bbbbb")); err != nil {
+		t.Fatal(err)
+	}
+	// This third put should evict k1 (least recently used) to stay <= 10.
+	if err := dc.Put(k3, []byte("ccccc")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := dc.Get(k1); ok {
+		t.Error("expected k1 to have been evicted")
+	}
+	if data, ok := dc.Get(k2); !ok || string(data) != "bbbbb" {
+		t.Error("expected k2 to still be cached")
+	}
+	if data, ok := dc.Get(k3); !ok || string(data) != "ccccc" {
+		t.Error("expected k3 to be cached")
+	}
+
+	size, maxSize := dc.Size()
+	if size != 10 || maxSize != 10 {
+		t.Errorf("unexpected size/maxSize: %d/%d", size, maxSize)
+	}
+
+	// Reload from disk and confirm the index round-trips.
+	dc2, err := NewDownloadCache(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := dc2.Get(k2); !ok {
+		t.Error("expected k2 to survive reload")
+	}
+	if _, ok := dc2.Get(k3); !ok {
+		t.Error("expected k3 to survive reload")
+	}
+
+	if err := dc2.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := dc2.Get(k2); ok {
+		t.Error("expected flush to clear the cache")
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if e.Name() != "cache.json" {
+			t.Errorf("expected flush to remove backing files, found %s", e.Name())
+		}
+	}
+}
+
+func TestCacheResize(t *testing.T) {
+	dir := t.TempDir()
+	dc, err := NewDownloadCache(dir, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dc.Put(cacheKey{Offset: 0}, make([]byte, 50))
+	dc.Put(cacheKey{Offset: 1}, make([]byte, 50))
+	if err := dc.Resize(50); err != nil {
+		t.Fatal(err)
+	}
+	size, maxSize := dc.Size()
+	if maxSize != 50 || size > 50 {
+		t.Errorf("unexpected size/maxSize after resize: %d/%d", size, maxSize)
+	}
+}
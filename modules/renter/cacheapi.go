@@ -0,0 +1,29 @@
+package renter
+
+// CacheInfo returns the current and maximum size, in bytes, of the renter's
+// on-disk download cache. It backs the GET /renter/cache HTTP endpoint.
+func (r *Renter) CacheInfo() (size, maxSize uint64) {
+	if r.cache == nil {
+		return 0, 0
+	}
+	return r.cache.Size()
+}
+
+// CacheFlush empties the renter's on-disk download cache. It backs the
+// POST /renter/cache/flush HTTP endpoint.
+func (r *Renter) CacheFlush() error {
+	if r.cache == nil {
+		return nil
+	}
+	return r.cache.Flush()
+}
+
+// CacheResize changes the maximum size of the renter's on-disk download
+// cache, evicting entries if necessary. It backs the
+// POST /renter/cache/resize HTTP endpoint.
+func (r *Renter) CacheResize(maxSize uint64) error {
+	if r.cache == nil {
+		return nil
+	}
+	return r.cache.Resize(maxSize)
+}
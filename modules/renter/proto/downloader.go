@@ -22,6 +22,7 @@ type Downloader struct {
 	closeChan   chan struct{}
 	once        sync.Once
 	hdb         hostDB
+	stats       *HostStatsTracker
 
 	SaveFn revisionSaver
 }
@@ -29,7 +30,27 @@ type Downloader struct {
 // Sector retrieves the sector with the specified Merkle root, and revises
 // the underlying contract to pay the host proportionally to the data
 // retrieve.
-func (hd *Downloader) Sector(root crypto.Hash) (_ modules.RenterContract, _ []byte, err error) {
+func (hd *Downloader) Sector(root crypto.Hash) (modules.RenterContract, []byte, error) {
+	return hd.SectorRange(root, 0, uint32(modules.SectorSize))
+}
+
+// SectorRange retrieves 'length' bytes of the sector with the specified
+// Merkle root, beginning at 'offset', and revises the underlying contract to
+// pay the host for the bytes it transfers. For a host marked via
+// MarkRangeProofSupport, only the requested range is transferred and paid
+// for. For any other host, a partial request still transfers and pays for
+// the whole sector, and the requested range is trimmed out locally.
+func (hd *Downloader) SectorRange(root crypto.Hash, offset, length uint32) (_ modules.RenterContract, _ []byte, err error) {
+	if uint64(offset)+uint64(length) > modules.SectorSize {
+		return modules.RenterContract{}, nil, errors.New("requested range is out-of-bounds")
+	} else if length == 0 {
+		return modules.RenterContract{}, nil, errors.New("length must be greater than zero")
+	} else if offset%segmentSize != 0 || length%segmentSize != 0 {
+		// Range proofs are verified at Merkle-leaf granularity, so partial
+		// reads must be leaf-aligned.
+		return modules.RenterContract{}, nil, errors.New("offset and length must be multiples of the segment size")
+	}
+
 	// Reset deadline when finished.
 	defer extendDeadline(hd.conn, time.Hour) // TODO: Constant.
 
@@ -40,8 +61,21 @@ func (hd *Downloader) Sector(root crypto.Hash) (_ modules.RenterContract, _ []by
 	}
 	defer func() { hd.contractSet.Return(contract) }()
 
-	// calculate price
-	sectorPrice := hd.host.DownloadBandwidthPrice.Mul64(modules.SectorSize)
+	// A partial read only saves bandwidth if the host actually sends back a
+	// Merkle range proof to verify it. Until this host is confirmed (via
+	// MarkRangeProofSupport) to support that extension, request the whole
+	// sector instead and trim the caller's range out of it locally below -
+	// the same safe, pre-existing behavior for every host until a paired
+	// host-side change ships and negotiates support.
+	wireOffset, wireLength := offset, length
+	partial := offset != 0 || length != uint32(modules.SectorSize)
+	if partial && !hostSupportsRangeProofs(contract.HostPublicKey) {
+		wireOffset, wireLength = 0, uint32(modules.SectorSize)
+	}
+
+	// calculate price, proportional to the number of bytes actually
+	// requested from the host rather than always the full sector
+	sectorPrice := hd.host.DownloadBandwidthPrice.Mul64(uint64(wireLength))
 	if contract.RenterFunds().Cmp(sectorPrice) < 0 {
 		return modules.RenterContract{}, nil, errors.New("contract has insufficient funds to support download")
 	}
@@ -73,20 +107,26 @@ func (hd *Downloader) Sector(root crypto.Hash) (_ modules.RenterContract, _ []by
 	extendDeadline(hd.conn, 2*time.Minute) // TODO: Constant.
 	err = encoding.WriteObject(hd.conn, []modules.DownloadAction{{
 		MerkleRoot: root,
-		Offset:     0,
-		Length:     modules.SectorSize,
+		Offset:     wireOffset,
+		Length:     wireLength,
 	}})
 	if err != nil {
 		return modules.RenterContract{}, nil, err
 	}
 
-	// Increase Successful/Failed interactions accordingly
+	// Increase Successful/Failed interactions accordingly, and fold the
+	// round trip into this host's EWMA latency/throughput stats so the
+	// download scheduler can rank it against other candidate hosts.
+	requestStart := time.Now()
 	defer func() {
 		if err != nil {
 			hd.hdb.IncrementFailedInteractions(contract.HostPublicKey)
 		} else if err == nil {
 			hd.hdb.IncrementSuccessfulInteractions(contract.HostPublicKey)
 		}
+		if hd.stats != nil {
+			hd.stats.Update(contract.HostPublicKey, time.Since(requestStart), int(wireLength), err == nil)
+		}
 	}()
 
 	// send the revision to the host for approval
@@ -104,16 +144,42 @@ func (hd *Downloader) Sector(root crypto.Hash) (_ modules.RenterContract, _ []by
 	// read sector data, completing one iteration of the download loop
 	extendDeadline(hd.conn, modules.NegotiateDownloadTime)
 	var sectors [][]byte
-	if err := encoding.ReadObject(hd.conn, &sectors, modules.SectorSize+16); err != nil {
+	if err := encoding.ReadObject(hd.conn, &sectors, uint64(wireLength)+16); err != nil {
 		return modules.RenterContract{}, nil, err
 	} else if len(sectors) != 1 {
 		return modules.RenterContract{}, nil, errors.New("host did not send enough sectors")
 	}
 	sector := sectors[0]
-	if uint64(len(sector)) != modules.SectorSize {
+	if uint32(len(sector)) != wireLength {
 		return modules.RenterContract{}, nil, errors.New("host did not send enough sector data")
-	} else if crypto.MerkleRoot(sector) != root {
-		return modules.RenterContract{}, nil, errors.New("host sent bad sector data")
+	}
+	if wireOffset == 0 && wireLength == uint32(modules.SectorSize) {
+		if crypto.MerkleRoot(sector) != root {
+			return modules.RenterContract{}, nil, errors.New("host sent bad sector data")
+		}
+	} else {
+		// A partial read can't be verified with a plain MerkleRoot check, so
+		// the host must also supply a Merkle range proof tying the returned
+		// bytes back to the sector's known root. We only reach here when
+		// hostSupportsRangeProofs confirmed the host will actually send one.
+		extendDeadline(hd.conn, modules.NegotiateDownloadTime)
+		var proofSet []crypto.Hash
+		if err := encoding.ReadObject(hd.conn, &proofSet, uint64(modules.SectorSize)); err != nil {
+			return modules.RenterContract{}, nil, err
+		}
+		numLeaves := int(modules.SectorSize / segmentSize)
+		rangeStart := int(wireOffset / segmentSize)
+		rangeEnd := int((wireOffset + wireLength) / segmentSize)
+		if !verifyRangeProof(sector, proofSet, rangeStart, rangeEnd, numLeaves, root) {
+			return modules.RenterContract{}, nil, errors.New("host sent an invalid Merkle range proof")
+		}
+	}
+
+	// If the host isn't known to support range proofs, the request above
+	// fetched (and verified) the whole sector regardless of what was asked
+	// for; trim the caller's actual requested range out of it here.
+	if wireOffset != offset || wireLength != length {
+		sector = sector[offset-wireOffset : offset-wireOffset+length]
 	}
 
 	// update contract and metrics
@@ -207,5 +273,6 @@ func NewDownloader(host modules.HostDBEntry, id types.FileContractID, contractSe
 		conn:        conn,
 		closeChan:   closeChan,
 		hdb:         hdb,
+		stats:       defaultHostStats,
 	}, nil
 }
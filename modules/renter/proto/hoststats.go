@@ -0,0 +1,118 @@
+package proto
+
+import (
+	"sync"
+	"time"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// ewmaDecay controls how quickly a host's EWMA latency/throughput estimates
+// adapt to new samples. A smaller value produces a smoother, slower-moving
+// average.
+const ewmaDecay = 0.2
+
+// failurePenaltyMS is added to the latency sample of a failed download, so
+// that flaky hosts reliably sink below any host that is merely slow.
+const failurePenaltyMS = 10000
+
+// hostStats tracks an exponentially-weighted moving average of a host's
+// download latency and throughput.
+type hostStats struct {
+	latencyMS     float64
+	throughputBPS float64
+	samples       int
+}
+
+// update folds a single download sample into the EWMA estimates. A failed
+// request is charged failurePenaltyMS and zero throughput.
+func (hs *hostStats) update(latency time.Duration, bytes int, success bool) {
+	latencyMS := float64(latency) / float64(time.Millisecond)
+	if !success {
+		latencyMS += failurePenaltyMS
+		bytes = 0
+	}
+	var throughputBPS float64
+	if latency > 0 {
+		throughputBPS = float64(bytes) / latency.Seconds()
+	}
+	if hs.samples == 0 {
+		hs.latencyMS, hs.throughputBPS = latencyMS, throughputBPS
+	} else {
+		hs.latencyMS += ewmaDecay * (latencyMS - hs.latencyMS)
+		hs.throughputBPS += ewmaDecay * (throughputBPS - hs.throughputBPS)
+	}
+	hs.samples++
+}
+
+// HostStatsTracker maintains per-host EWMA latency/throughput stats, keyed
+// by host public key. It is updated from the same call sites that already
+// report IncrementSuccessfulInteractions/IncrementFailedInteractions, and is
+// consulted by the renter's download scheduler to rank candidate hosts.
+type HostStatsTracker struct {
+	stats map[string]*hostStats
+	mu    sync.Mutex
+}
+
+// NewHostStatsTracker returns an empty HostStatsTracker.
+func NewHostStatsTracker() *HostStatsTracker {
+	return &HostStatsTracker{stats: make(map[string]*hostStats)}
+}
+
+// defaultHostStats is the tracker used by Downloaders that aren't
+// constructed with one of their own, so that stats accumulate across
+// Downloader instances for the lifetime of the process.
+var defaultHostStats = NewHostStatsTracker()
+
+// DefaultHostStats returns the package-wide HostStatsTracker that every
+// Downloader reports its EWMA samples to. It lets callers outside this
+// package - e.g. the renter's download scheduler - rank candidate hosts
+// with the same data Downloaders themselves accumulate.
+func DefaultHostStats() *HostStatsTracker {
+	return defaultHostStats
+}
+
+// Update records a single download sample for host.
+func (t *HostStatsTracker) Update(host types.SiaPublicKey, latency time.Duration, bytes int, success bool) {
+	key := host.String()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	hs, ok := t.stats[key]
+	if !ok {
+		hs = &hostStats{}
+		t.stats[key] = hs
+	}
+	hs.update(latency, bytes, success)
+}
+
+// unknownHostLatencyMS is the assumed latency of a host with no track
+// record yet. It sits well below failurePenaltyMS (so a flaky host still
+// ranks worse than an untested one) and is a plausible real-world latency
+// (so a known-fast host still ranks ahead of an untested one).
+const unknownHostLatencyMS = 100
+
+// rank returns a copy of the current stats for host. A host with no samples
+// yet reports an assumed unknownHostLatencyMS, rather than zero, so that it
+// doesn't spuriously outrank every host with an actual track record.
+func (t *HostStatsTracker) rank(host types.SiaPublicKey) hostStats {
+	key := host.String()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if hs, ok := t.stats[key]; ok {
+		return *hs
+	}
+	return hostStats{latencyMS: unknownHostLatencyMS}
+}
+
+// Less reports whether host a should be preferred over host b when racing
+// candidates for the same piece: lower EWMA latency wins, ties broken by
+// higher EWMA throughput. A host with no track record yet is treated as
+// unknown-but-promising, ranking ahead of a host with a poor track record
+// but behind one with a good one.
+func (t *HostStatsTracker) Less(a, b types.SiaPublicKey) bool {
+	sa, sb := t.rank(a), t.rank(b)
+	if sa.latencyMS != sb.latencyMS {
+		return sa.latencyMS < sb.latencyMS
+	}
+	return sa.throughputBPS > sb.throughputBPS
+}
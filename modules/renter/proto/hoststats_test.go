@@ -0,0 +1,52 @@
+package proto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestHostStatsTrackerLess verifies that a fast, reliable host is preferred
+// over a slow host, and that a slow host is preferred over one that keeps
+// failing.
+func TestHostStatsTrackerLess(t *testing.T) {
+	var fast, slow, flaky types.SiaPublicKey
+	fast.Key = []byte("fast")
+	slow.Key = []byte("slow")
+	flaky.Key = []byte("flaky")
+
+	tracker := NewHostStatsTracker()
+	for i := 0; i < 5; i++ {
+		tracker.Update(fast, 10*time.Millisecond, 1<<20, true)
+		tracker.Update(slow, 200*time.Millisecond, 1<<20, true)
+		tracker.Update(flaky, 10*time.Millisecond, 1<<20, false)
+	}
+
+	if !tracker.Less(fast, slow) {
+		t.Error("expected fast host to be preferred over slow host")
+	}
+	if tracker.Less(slow, fast) {
+		t.Error("did not expect slow host to be preferred over fast host")
+	}
+	if !tracker.Less(slow, flaky) {
+		t.Error("expected slow-but-reliable host to be preferred over a flaky host")
+	}
+}
+
+// TestHostStatsTrackerUnknownHost verifies that a host with no samples yet
+// ranks ahead of one with a poor track record.
+func TestHostStatsTrackerUnknownHost(t *testing.T) {
+	var unknown, flaky types.SiaPublicKey
+	unknown.Key = []byte("unknown")
+	flaky.Key = []byte("flaky")
+
+	tracker := NewHostStatsTracker()
+	for i := 0; i < 5; i++ {
+		tracker.Update(flaky, 10*time.Millisecond, 1<<20, false)
+	}
+
+	if !tracker.Less(unknown, flaky) {
+		t.Error("expected an untested host to be preferred over a flaky one")
+	}
+}
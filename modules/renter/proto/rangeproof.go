@@ -0,0 +1,96 @@
+package proto
+
+import (
+	"github.com/NebulousLabs/Sia/crypto"
+)
+
+// segmentSize is the leaf size used by Sia's sector Merkle trees.
+const segmentSize = 64
+
+// leafHash hashes a single segmentSize-byte Merkle leaf, prefixed
+// with 0x00 to distinguish leaves from interior nodes, matching the
+// convention used throughout Sia's Merkle trees (e.g. crypto.MerkleRoot).
+func leafHash(leaf []byte) crypto.Hash {
+	return crypto.HashBytes(append([]byte{0}, leaf...))
+}
+
+// nodeHash hashes two child nodes together, prefixed with 0x01 to
+// distinguish interior nodes from leaves.
+func nodeHash(left, right crypto.Hash) crypto.Hash {
+	return crypto.HashBytes(append([]byte{1}, append(left[:], right[:]...)...))
+}
+
+// largestPowerOfTwoLessThan returns the largest power of two strictly less
+// than n, which is the size Sia's Merkle trees give to the left subtree of
+// a node covering n leaves.
+func largestPowerOfTwoLessThan(n int) int {
+	p := 1
+	for p*2 < n {
+		p *= 2
+	}
+	return p
+}
+
+// subtreeRoot recomputes the Merkle root of the subtree covering leaves
+// [lo, hi) of a tree with 'n' total leaves. Leaves inside [rangeStart,
+// rangeEnd) are consumed from 'leaves' (which the caller has hashed from
+// the data it is trying to verify); every other leaf's hash is consumed
+// from 'proof', the sibling hashes supplied by the host. Returns false if
+// either slice runs out early, which indicates a malformed proof.
+func subtreeRoot(lo, hi, rangeStart, rangeEnd int, leaves, proof *[]crypto.Hash) (crypto.Hash, bool) {
+	if hi <= rangeStart || lo >= rangeEnd {
+		if len(*proof) == 0 {
+			return crypto.Hash{}, false
+		}
+		h := (*proof)[0]
+		*proof = (*proof)[1:]
+		return h, true
+	}
+	if hi-lo == 1 {
+		if len(*leaves) == 0 {
+			return crypto.Hash{}, false
+		}
+		h := (*leaves)[0]
+		*leaves = (*leaves)[1:]
+		return h, true
+	}
+	mid := lo + largestPowerOfTwoLessThan(hi-lo)
+	left, ok := subtreeRoot(lo, mid, rangeStart, rangeEnd, leaves, proof)
+	if !ok {
+		return crypto.Hash{}, false
+	}
+	right, ok := subtreeRoot(mid, hi, rangeStart, rangeEnd, leaves, proof)
+	if !ok {
+		return crypto.Hash{}, false
+	}
+	return nodeHash(left, right), true
+}
+
+// verifyRangeProof verifies that 'data' - the bytes covering segments
+// [rangeStart, rangeEnd) of a segmentSize-leaf Merkle tree of
+// 'numLeaves' total leaves - combined with 'proofSet' (the sibling hashes
+// needed to recompute every part of the tree outside the proven range)
+// hashes up to 'root'. This lets SectorRange confirm that a host's response
+// to a partial sector read actually came from the sector with the
+// requested Merkle root, without requiring the full sector to be
+// downloaded and hashed.
+func verifyRangeProof(data []byte, proofSet []crypto.Hash, rangeStart, rangeEnd, numLeaves int, root crypto.Hash) bool {
+	if rangeStart < 0 || rangeEnd <= rangeStart || rangeEnd > numLeaves {
+		return false
+	}
+	if len(data) != (rangeEnd-rangeStart)*segmentSize {
+		return false
+	}
+
+	leaves := make([]crypto.Hash, rangeEnd-rangeStart)
+	for i := range leaves {
+		leaves[i] = leafHash(data[i*segmentSize : (i+1)*segmentSize])
+	}
+	proof := append([]crypto.Hash(nil), proofSet...)
+
+	got, ok := subtreeRoot(0, numLeaves, rangeStart, rangeEnd, &leaves, &proof)
+	if !ok || len(leaves) != 0 || len(proof) != 0 {
+		return false
+	}
+	return got == root
+}
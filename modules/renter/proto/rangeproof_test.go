@@ -0,0 +1,111 @@
+package proto
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+)
+
+// buildTree builds a full reference Merkle tree over numLeaves segments of
+// data and returns its root plus the hash of every leaf.
+func buildTree(data []byte, numLeaves int) (crypto.Hash, []crypto.Hash) {
+	leaves := make([]crypto.Hash, numLeaves)
+	for i := 0; i < numLeaves; i++ {
+		leaves[i] = leafHash(data[i*segmentSize : (i+1)*segmentSize])
+	}
+	var build func(lo, hi int) crypto.Hash
+	build = func(lo, hi int) crypto.Hash {
+		if hi-lo == 1 {
+			return leaves[lo]
+		}
+		mid := lo + largestPowerOfTwoLessThan(hi-lo)
+		return nodeHash(build(lo, mid), build(mid, hi))
+	}
+	return build(0, numLeaves), leaves
+}
+
+// buildProof returns the proof set an honest host would supply for range
+// [rangeStart, rangeEnd) by walking the same recursive structure and
+// collecting every sibling hash that falls entirely outside the range.
+func buildProof(leaves []crypto.Hash, lo, hi, rangeStart, rangeEnd int) []crypto.Hash {
+	if hi <= rangeStart || lo >= rangeEnd {
+		// Build this subtree's hash and return it as a single proof hash.
+		var build func(lo, hi int) crypto.Hash
+		build = func(lo, hi int) crypto.Hash {
+			if hi-lo == 1 {
+				return leaves[lo]
+			}
+			mid := lo + largestPowerOfTwoLessThan(hi-lo)
+			return nodeHash(build(lo, mid), build(mid, hi))
+		}
+		return []crypto.Hash{build(lo, hi)}
+	}
+	if hi-lo == 1 {
+		return nil
+	}
+	mid := lo + largestPowerOfTwoLessThan(hi-lo)
+	var proof []crypto.Hash
+	proof = append(proof, buildProof(leaves, lo, mid, rangeStart, rangeEnd)...)
+	proof = append(proof, buildProof(leaves, mid, hi, rangeStart, rangeEnd)...)
+	return proof
+}
+
+func TestVerifyRangeProof(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	numLeaves := 17 // deliberately not a power of two
+	data := make([]byte, numLeaves*segmentSize)
+	r.Read(data)
+
+	root, leaves := buildTree(data, numLeaves)
+
+	for _, rng := range [][2]int{{0, numLeaves}, {0, 1}, {5, 9}, {16, 17}, {3, 16}} {
+		start, end := rng[0], rng[1]
+		rangeData := data[start*segmentSize : end*segmentSize]
+		proof := buildProof(leaves, 0, numLeaves, start, end)
+		if !verifyRangeProof(rangeData, proof, start, end, numLeaves, root) {
+			t.Errorf("valid proof for range [%d,%d) rejected", start, end)
+		}
+	}
+}
+
+func TestVerifyRangeProofRejectsTamperedData(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	numLeaves := 9
+	data := make([]byte, numLeaves*segmentSize)
+	r.Read(data)
+	root, leaves := buildTree(data, numLeaves)
+
+	start, end := 2, 5
+	rangeData := append([]byte(nil), data[start*segmentSize:end*segmentSize]...)
+	proof := buildProof(leaves, 0, numLeaves, start, end)
+
+	if !verifyRangeProof(rangeData, proof, start, end, numLeaves, root) {
+		t.Fatal("expected untampered proof to verify")
+	}
+
+	tampered := append([]byte(nil), rangeData...)
+	tampered[0] ^= 0xFF
+	if verifyRangeProof(tampered, proof, start, end, numLeaves, root) {
+		t.Error("expected tampered sector data to be rejected")
+	}
+
+	badProof := append([]crypto.Hash(nil), proof...)
+	if len(badProof) > 0 {
+		badProof[0][0] ^= 0xFF
+		if verifyRangeProof(rangeData, badProof, start, end, numLeaves, root) {
+			t.Error("expected tampered proof set to be rejected")
+		}
+	}
+}
+
+func TestVerifyRangeProofRejectsWrongLength(t *testing.T) {
+	numLeaves := 4
+	data := bytes.Repeat([]byte{1}, numLeaves*segmentSize)
+	root, leaves := buildTree(data, numLeaves)
+	proof := buildProof(leaves, 0, numLeaves, 1, 3)
+	if verifyRangeProof(data[:segmentSize], proof, 1, 3, numLeaves, root) {
+		t.Error("expected mismatched data length to be rejected")
+	}
+}
@@ -0,0 +1,38 @@
+package proto
+
+import (
+	"sync"
+
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// rangeProofHosts tracks which hosts are known to support sending a Merkle
+// range proof after a partial sector read. A host that hasn't been taught to
+// send one will, after returning the requested bytes, simply move on to
+// whatever it expects next - it will never send the extra proof object, so
+// waiting to read one would block SectorRange until the connection deadline
+// rather than fail fast. A host is absent from this set (and so assumed not
+// to support the extension) until something confirms otherwise.
+var (
+	rangeProofHostsMu sync.Mutex
+	rangeProofHosts   = make(map[string]bool)
+)
+
+// MarkRangeProofSupport records that host is known to support the Merkle
+// range proof extension to the download RPC, e.g. because a version/feature
+// negotiation elsewhere confirmed it. Until this is called for a given
+// host, SectorRange falls back to fetching and locally verifying the whole
+// sector for that host, regardless of the offset/length requested.
+func MarkRangeProofSupport(host types.SiaPublicKey) {
+	rangeProofHostsMu.Lock()
+	defer rangeProofHostsMu.Unlock()
+	rangeProofHosts[host.String()] = true
+}
+
+// hostSupportsRangeProofs reports whether host has been confirmed to support
+// the Merkle range proof extension.
+func hostSupportsRangeProofs(host types.SiaPublicKey) bool {
+	rangeProofHostsMu.Lock()
+	defer rangeProofHostsMu.Unlock()
+	return rangeProofHosts[host.String()]
+}
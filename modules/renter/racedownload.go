@@ -0,0 +1,132 @@
+package renter
+
+import (
+	"sort"
+
+	"github.com/NebulousLabs/Sia/modules/renter/proto"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/errors"
+)
+
+// errNoCandidates is returned when a piece has no candidate hosts to race.
+var errNoCandidates = errors.New("no candidate hosts for piece")
+
+// pieceFetch fetches a single piece from one candidate host. It must
+// respect cancellation promptly: once the piece is no longer needed, the
+// scheduler stops waiting on it (though the fetch itself may still be
+// in-flight on the wire).
+//
+// hostPublicKey identifies the host the candidate would hit, so that
+// raceHostsForPiece can rank candidates against each other via
+// proto.HostStatsTracker.Less before racing them. A candidate with no
+// meaningful distinct host identity (e.g. a duplicate attempt through a
+// scheduler that doesn't yet expose per-host dispatch) can leave this at
+// its zero value; Less treats an unknown key as an untested host.
+type pieceFetch struct {
+	hostKey       string
+	hostPublicKey types.SiaPublicKey
+	fetch         func(cancel <-chan struct{}) ([]byte, error)
+}
+
+// pieceResult is the outcome of a single pieceFetch.
+type pieceResult struct {
+	hostKey string
+	data    []byte
+	err     error
+}
+
+// sortCandidatesByStats orders candidates best-first according to stats, so
+// that raceHostsForPiece's overfetch budget is spent on the most promising
+// hosts rather than on whatever order the caller happened to build the
+// slice in. Candidates that share a host identity (or share the zero value,
+// because the caller had no distinct identity to give them) sort stably
+// relative to each other.
+func sortCandidatesByStats(candidates []pieceFetch, stats *proto.HostStatsTracker) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return stats.Less(candidates[i].hostPublicKey, candidates[j].hostPublicKey)
+	})
+}
+
+// raceHostsForPiece dispatches 'overfetch' extra candidates on top of the
+// single host that would normally serve a piece, ordering them best-first
+// via proto.DefaultHostStats().Less before dispatch, and returns as soon as
+// any one of them succeeds. The remaining in-flight candidates are
+// signalled to cancel via their own cancel channel, but raceHostsForPiece
+// does not block waiting for them to actually stop - it returns as soon as
+// a winner is known.
+//
+// overfetch is the number of redundant candidates to race beyond the first;
+// overfetch=0 degrades to issuing a single request, same as today.
+func raceHostsForPiece(candidates []pieceFetch, overfetch int) (pieceResult, error) {
+	if len(candidates) == 0 {
+		return pieceResult{}, errNoCandidates
+	}
+	ordered := append([]pieceFetch(nil), candidates...)
+	sortCandidatesByStats(ordered, proto.DefaultHostStats())
+
+	n := overfetch + 1
+	if n > len(ordered) {
+		n = len(ordered)
+	}
+
+	cancel := make(chan struct{})
+	results := make(chan pieceResult, n)
+	for _, c := range ordered[:n] {
+		c := c
+		go func() {
+			data, err := c.fetch(cancel)
+			results <- pieceResult{hostKey: c.hostKey, data: data, err: err}
+		}()
+	}
+
+	var lastErr error
+	for i := 0; i < n; i++ {
+		res := <-results
+		if res.err == nil {
+			close(cancel)
+			return res, nil
+		}
+		lastErr = res.err
+	}
+	close(cancel)
+	return pieceResult{}, lastErr
+}
+
+// raceDownloadPieces races candidates for each of 'minimumPieces' pieces in
+// parallel, each with its own set of 'overfetch' redundant candidates, and
+// returns once enough pieces have arrived to decode the chunk (or the first
+// unrecoverable error).
+func raceDownloadPieces(pieceCandidates [][]pieceFetch, overfetch int) ([][]byte, error) {
+	type indexedResult struct {
+		index int
+		res   pieceResult
+		err   error
+	}
+
+	out := make(chan indexedResult, len(pieceCandidates))
+	for i, candidates := range pieceCandidates {
+		i, candidates := i, candidates
+		go func() {
+			res, err := raceHostsForPiece(candidates, overfetch)
+			out <- indexedResult{index: i, res: res, err: err}
+		}()
+	}
+
+	pieces := make([][]byte, len(pieceCandidates))
+	var firstErr error
+	for range pieceCandidates {
+		ir := <-out
+		if ir.err != nil {
+			if firstErr == nil {
+				firstErr = ir.err
+			}
+			continue
+		}
+		pieces[ir.index] = ir.res.data
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return pieces, nil
+}
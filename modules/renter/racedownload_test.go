@@ -0,0 +1,228 @@
+package renter
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/errors"
+)
+
+// fakeFetch returns a pieceFetch that sleeps for 'delay' before returning
+// 'data', or fails if 'fail' is true. If cancelled before 'delay' elapses,
+// it returns early with an error so the race doesn't wait on it.
+func fakeFetch(hostKey string, delay time.Duration, data []byte, fail bool) pieceFetch {
+	return pieceFetch{
+		hostKey: hostKey,
+		fetch: func(cancel <-chan struct{}) ([]byte, error) {
+			select {
+			case <-time.After(delay):
+			case <-cancel:
+				return nil, errors.New("cancelled")
+			}
+			if fail {
+				return nil, errors.New("simulated host failure")
+			}
+			return data, nil
+		},
+	}
+}
+
+// TestRaceHostsForPieceTailLatency verifies that racing a fast host
+// alongside a slow one returns as soon as the fast host answers, rather than
+// waiting on the slow straggler.
+func TestRaceHostsForPieceTailLatency(t *testing.T) {
+	candidates := []pieceFetch{
+		fakeFetch("slow", 200*time.Millisecond, []byte("slow-data"), false),
+		fakeFetch("fast", 10*time.Millisecond, []byte("fast-data"), false),
+	}
+
+	start := time.Now()
+	res, err := raceHostsForPiece(candidates, 1)
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.hostKey != "fast" {
+		t.Errorf("expected fast host to win the race, got %q", res.hostKey)
+	}
+	if elapsed > 100*time.Millisecond {
+		t.Errorf("race took %v, expected it to return as soon as the fast host answered", elapsed)
+	}
+}
+
+// TestRaceHostsForPieceFlakyHost verifies that a failing host doesn't
+// prevent a slower-but-working host from completing the piece.
+func TestRaceHostsForPieceFlakyHost(t *testing.T) {
+	candidates := []pieceFetch{
+		fakeFetch("flaky", 5*time.Millisecond, nil, true),
+		fakeFetch("reliable", 50*time.Millisecond, []byte("good-data"), false),
+	}
+
+	res, err := raceHostsForPiece(candidates, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.hostKey != "reliable" {
+		t.Errorf("expected reliable host to win once the flaky host failed, got %q", res.hostKey)
+	}
+}
+
+// TestRaceHostsForPieceAllFail verifies that the race surfaces an error when
+// every candidate fails.
+func TestRaceHostsForPieceAllFail(t *testing.T) {
+	candidates := []pieceFetch{
+		fakeFetch("a", time.Millisecond, nil, true),
+		fakeFetch("b", time.Millisecond, nil, true),
+	}
+	if _, err := raceHostsForPiece(candidates, 1); err == nil {
+		t.Fatal("expected an error when every candidate fails")
+	}
+}
+
+// TestRaceDownloadPiecesCancelsStragglers verifies that once enough pieces
+// have been decoded, the still-in-flight duplicate candidates are signalled
+// to cancel rather than left to run to completion.
+func TestRaceDownloadPiecesCancelsStragglers(t *testing.T) {
+	var cancelledCount int32
+	slowButCancellable := func(hostKey string) pieceFetch {
+		return pieceFetch{
+			hostKey: hostKey,
+			fetch: func(cancel <-chan struct{}) ([]byte, error) {
+				select {
+				case <-time.After(500 * time.Millisecond):
+					return []byte("too-slow"), nil
+				case <-cancel:
+					atomic.AddInt32(&cancelledCount, 1)
+					return nil, errors.New("cancelled")
+				}
+			},
+		}
+	}
+
+	pieceCandidates := [][]pieceFetch{
+		{fakeFetch("fast0", 5*time.Millisecond, []byte("piece0"), false), slowButCancellable("slow0")},
+		{fakeFetch("fast1", 5*time.Millisecond, []byte("piece1"), false), slowButCancellable("slow1")},
+	}
+
+	pieces, err := raceDownloadPieces(pieceCandidates, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(pieces[0]) != "piece0" || string(pieces[1]) != "piece1" {
+		t.Fatalf("unexpected pieces: %q", pieces)
+	}
+
+	// Give the cancelled goroutines a moment to observe the cancel signal.
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&cancelledCount) != 2 {
+		t.Errorf("expected both straggler fetches to be cancelled, got %d", cancelledCount)
+	}
+}
+
+// fakeContractSet stands in for proto.ContractSet, which this tree doesn't
+// define. It mirrors the real type's Acquire/Return contract: Acquire checks
+// a contract out, and every Acquire must be paired with a Return, whether
+// the fetch it backs wins the race or is cancelled as a straggler.
+type fakeContractSet struct {
+	mu       sync.Mutex
+	acquired map[string]bool
+	returned map[string]int
+}
+
+func newFakeContractSet(hostKeys ...string) *fakeContractSet {
+	cs := &fakeContractSet{
+		acquired: make(map[string]bool),
+		returned: make(map[string]int),
+	}
+	for _, k := range hostKeys {
+		cs.acquired[k] = false
+	}
+	return cs
+}
+
+func (cs *fakeContractSet) Acquire(hostKey string) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.acquired[hostKey] {
+		return false
+	}
+	cs.acquired[hostKey] = true
+	return true
+}
+
+func (cs *fakeContractSet) Return(hostKey string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.acquired[hostKey] = false
+	cs.returned[hostKey]++
+}
+
+func (cs *fakeContractSet) returnCount(hostKey string) int {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.returned[hostKey]
+}
+
+func (cs *fakeContractSet) isAcquired(hostKey string) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.acquired[hostKey]
+}
+
+// contractBackedFetch builds a pieceFetch that acquires hostKey from cs
+// before fetching and returns it - via defer, exactly like
+// proto.Downloader.SectorRange does with hd.contractSet - regardless of
+// whether the fetch succeeds, fails, or is cancelled as a race straggler.
+func contractBackedFetch(cs *fakeContractSet, hostKey string, delay time.Duration, data []byte) pieceFetch {
+	return pieceFetch{
+		hostKey: hostKey,
+		fetch: func(cancel <-chan struct{}) ([]byte, error) {
+			if !cs.Acquire(hostKey) {
+				return nil, errors.New("contract not present in contract set")
+			}
+			defer cs.Return(hostKey)
+
+			select {
+			case <-time.After(delay):
+			case <-cancel:
+				return nil, errors.New("cancelled")
+			}
+			return data, nil
+		},
+	}
+}
+
+// TestRaceHostsForPieceReturnsContracts verifies that every candidate's
+// contract is returned to its ContractSet once the race concludes - both
+// the winner's and the cancelled stragglers' - so that raceHostsForPiece
+// never leaks an acquired contract.
+func TestRaceHostsForPieceReturnsContracts(t *testing.T) {
+	cs := newFakeContractSet("fast", "slow0", "slow1")
+	candidates := []pieceFetch{
+		contractBackedFetch(cs, "fast", 5*time.Millisecond, []byte("fast-data")),
+		contractBackedFetch(cs, "slow0", 500*time.Millisecond, []byte("slow0-data")),
+		contractBackedFetch(cs, "slow1", 500*time.Millisecond, []byte("slow1-data")),
+	}
+
+	res, err := raceHostsForPiece(candidates, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.hostKey != "fast" {
+		t.Fatalf("expected fast host to win the race, got %q", res.hostKey)
+	}
+
+	// Give the cancelled stragglers a moment to observe the cancel signal
+	// and run their deferred Return calls.
+	time.Sleep(50 * time.Millisecond)
+	for _, hostKey := range []string{"fast", "slow0", "slow1"} {
+		if cs.returnCount(hostKey) != 1 {
+			t.Errorf("expected %q's contract to be returned exactly once, got %d", hostKey, cs.returnCount(hostKey))
+		}
+		if cs.isAcquired(hostKey) {
+			t.Errorf("expected %q's contract to no longer be held after the race", hostKey)
+		}
+	}
+}
@@ -10,6 +10,15 @@ import (
 // download to the renter's downloader, and then using the data that gets
 // returned.
 func (r *Renter) managedDownloadLogicalChunkData(chunk *unfinishedChunk) error {
+	// Check the on-disk cache before dialing any host.
+	cacheKey := cacheKeyForChunk(chunk)
+	if r.cache != nil {
+		if data, ok := r.cache.Get(cacheKey); ok {
+			chunk.logicalChunkData = data
+			return nil
+		}
+	}
+
 	// Create the download, queue the download, and then wait for the download
 	// to finish.
 	//
@@ -19,6 +28,15 @@ func (r *Renter) managedDownloadLogicalChunkData(chunk *unfinishedChunk) error {
 	// more than download memory, and if we need to allocate two times in a row
 	// from the same memory pool while other processes are asynchronously doing
 	// the same, we risk deadlock.
+	//
+	// NOTE: raceHostsForPiece/raceDownloadPieces (racedownload.go) are meant
+	// to replace this single-attempt download once newSectionDownload (or
+	// its replacement) can hand out a distinct candidate per host. Racing
+	// duplicate attempts through today's newSectionDownload would just pay
+	// for and transfer the same chunk twice per repair for no benefit, since
+	// every attempt would hit the same multi-host download underneath, so
+	// until that per-host hook exists, this stays on the single-call path
+	// and the race scheduler is exercised only by its own unit tests.
 	buf := NewDownloadBufferWriter(chunk.length, chunk.offset)
 	// TODO: Should convert the inputs of newSectionDownload to use an int64 for
 	// the offset.
@@ -29,7 +47,18 @@ func (r *Renter) managedDownloadLogicalChunkData(chunk *unfinishedChunk) error {
 		return errors.New("repair download interrupted by stop call")
 	}
 	chunk.logicalChunkData = buf.Bytes()
-	return d.Err()
+	if err := d.Err(); err != nil {
+		return err
+	}
+
+	// Populate the cache opportunistically now that we've paid to fetch the
+	// data anyway.
+	if r.cache != nil {
+		if err := r.cache.Put(cacheKey, chunk.logicalChunkData); err != nil {
+			r.log.Debugln("Failed to cache downloaded chunk:", err)
+		}
+	}
+	return nil
 }
 
 // managedFetchLogicalChunkData will get the raw data for a chunk, pulling it from disk if
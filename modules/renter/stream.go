@@ -0,0 +1,180 @@
+package renter
+
+import (
+	"io"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/renter/proto"
+
+	"github.com/NebulousLabs/errors"
+)
+
+// streamDownloadWindowSize is the number of bytes fetched per pipelined
+// download window when serving a Stream. Fetching in windows smaller than
+// the full requested range lets the download of the next window proceed
+// while the caller is still consuming the current one. It equals
+// modules.SectorSize in standard builds. Only the final window of a Stream
+// can be shorter than a full window; fill() serves it with
+// downloadSectorTail when it lies within a single sector.
+const streamDownloadWindowSize = 1 << 22 // 4 MiB
+
+// streamWindow is a single pipelined window of a Stream download. It is
+// downloaded in a background goroutine and is ready for consumption once
+// 'done' is closed.
+type streamWindow struct {
+	data []byte
+	err  error
+	done chan struct{}
+}
+
+// streamReader is an io.ReadCloser that serves a byte range of a siafile by
+// pipelining fixed-size download windows across the worker pool: while the
+// caller consumes the current window, the next window is already being
+// downloaded in the background.
+type streamReader struct {
+	r    *Renter
+	file *file
+
+	nextOffset uint64
+	remaining  uint64
+	queue      []*streamWindow
+	buf        []byte
+}
+
+// Stream returns an io.ReadCloser that streams 'length' bytes of the file at
+// 'siapath' starting at 'offset'. Unlike a full-file download, Stream only
+// downloads the sector ranges that are actually needed to satisfy the
+// requested byte range, and pipelines those downloads across the worker
+// pool so that reads do not stall waiting on a single host round trip. This
+// allows consumers such as video players to seek into a file cheaply.
+func (r *Renter) Stream(siapath string, offset, length uint64) (io.ReadCloser, error) {
+	lockID := r.mu.RLock()
+	f, exists := r.files[siapath]
+	r.mu.RUnlock(lockID)
+	if !exists {
+		return nil, errors.New("no file with that path")
+	}
+	if offset+length > f.size {
+		return nil, errors.New("requested range is out-of-bounds")
+	}
+
+	sr := &streamReader{
+		r:          r,
+		file:       f,
+		nextOffset: offset,
+		remaining:  length,
+	}
+	sr.fill()
+	return sr, nil
+}
+
+// fill tops up the pipeline, keeping up to two windows queued and
+// downloading in the background, in addition to the window the caller has
+// already taken off the queue and is reading.
+func (sr *streamReader) fill() {
+	for len(sr.queue) < 2 && sr.remaining > 0 {
+		winLength := uint64(streamDownloadWindowSize)
+		if winLength > sr.remaining {
+			winLength = sr.remaining
+		}
+		winOffset := sr.nextOffset
+		sr.nextOffset += winLength
+		sr.remaining -= winLength
+
+		w := &streamWindow{done: make(chan struct{})}
+		sr.queue = append(sr.queue, w)
+		go func() {
+			defer close(w.done)
+
+			if winLength < streamDownloadWindowSize && winOffset%modules.SectorSize+winLength <= modules.SectorSize {
+				// This is the final window, and it lies within a single
+				// sector: fetch it via a direct SectorRange call against
+				// that sector's host instead of through newSectionDownload.
+				data, err := sr.r.downloadSectorTail(sr.file, winOffset, winLength)
+				if err != nil {
+					w.err = err
+					return
+				}
+				w.data = data
+				return
+			}
+
+			buf := NewDownloadBufferWriter(winLength, int64(winOffset))
+			d := sr.r.newSectionDownload(sr.file, buf, winOffset, winLength)
+			select {
+			case <-d.downloadFinished:
+			case <-sr.r.tg.StopChan():
+				w.err = errors.New("stream download interrupted by stop call")
+				return
+			}
+			if err := d.Err(); err != nil {
+				w.err = err
+				return
+			}
+			w.data = buf.Bytes()
+		}()
+	}
+}
+
+// downloadSectorTail fetches exactly ['offset', 'offset'+'length') from the
+// single sector that covers them, via a direct SectorRange call against
+// that sector's host rather than through the multi-host download
+// orchestrator. The request is widened to whole segments and the result
+// trimmed back to the requested bytes. Whether the host transfers only that
+// range or the whole sector is up to SectorRange. It is only valid for a
+// range that lies entirely within one sector, which fill() checks before
+// using it to serve the final, less-than-a-full-window piece of a Stream.
+func (r *Renter) downloadSectorTail(f *file, offset, length uint64) ([]byte, error) {
+	root, contractID, host, ok := f.sectorForOffset(offset)
+	if !ok {
+		return nil, errors.New("no sector covers the requested offset")
+	}
+	sectorOffset := uint32(offset % modules.SectorSize)
+
+	d, err := proto.NewDownloader(host, contractID, r.contractSet, r.hostContractor, r.tg.StopChan())
+	if err != nil {
+		return nil, err
+	}
+	defer d.Close()
+
+	// SectorRange only accepts segment-aligned ranges.
+	alignedOffset := sectorOffset - sectorOffset%crypto.SegmentSize
+	alignedEnd := sectorOffset + uint32(length)
+	if rem := alignedEnd % crypto.SegmentSize; rem != 0 {
+		alignedEnd += crypto.SegmentSize - rem
+	}
+	_, data, err := d.SectorRange(root, alignedOffset, alignedEnd-alignedOffset)
+	if err != nil {
+		return nil, err
+	}
+	start := sectorOffset - alignedOffset
+	return data[start : start+uint32(length)], nil
+}
+
+// Read implements io.Reader, serving bytes from the front of the pipeline
+// and topping up the pipeline as windows are consumed.
+func (sr *streamReader) Read(p []byte) (int, error) {
+	for len(sr.buf) == 0 {
+		if len(sr.queue) == 0 {
+			return 0, io.EOF
+		}
+		w := sr.queue[0]
+		<-w.done
+		sr.queue = sr.queue[1:]
+		if w.err != nil {
+			return 0, w.err
+		}
+		sr.buf = w.data
+		sr.fill()
+	}
+	n := copy(p, sr.buf)
+	sr.buf = sr.buf[n:]
+	return n, nil
+}
+
+// Close implements io.Closer. Any windows still in flight are left to
+// complete in the background; their results are simply discarded.
+func (sr *streamReader) Close() error {
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// registerCacheHandlers wires up the renter download-cache inspection,
+// flush, and resize endpoints.
+func (d *daemon) registerCacheHandlers() {
+	http.HandleFunc("/renter/cache", d.cacheHandler)
+	http.HandleFunc("/renter/cache/flush", d.cacheFlushHandler)
+	http.HandleFunc("/renter/cache/resize", d.cacheResizeHandler)
+}
+
+// cacheResponse is the JSON body returned by GET /renter/cache.
+type cacheResponse struct {
+	Size    uint64 `json:"size"`
+	MaxSize uint64 `json:"maxsize"`
+}
+
+// cacheHandler handles GET /renter/cache, reporting the current and maximum
+// size of the download cache.
+func (d *daemon) cacheHandler(w http.ResponseWriter, req *http.Request) {
+	size, maxSize := d.cache.CacheInfo()
+	json.NewEncoder(w).Encode(cacheResponse{Size: size, MaxSize: maxSize})
+}
+
+// cacheFlushHandler handles POST /renter/cache/flush, emptying the download
+// cache.
+func (d *daemon) cacheFlushHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "POST required", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := d.cache.CacheFlush(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// cacheResizeHandler handles POST /renter/cache/resize?size=<bytes>,
+// changing the download cache's maximum size.
+func (d *daemon) cacheResizeHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "POST required", http.StatusMethodNotAllowed)
+		return
+	}
+	size, err := strconv.ParseUint(req.FormValue("size"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid size: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := d.cache.CacheResize(size); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/NebulousLabs/Sia/consensus"
+	rentercache "github.com/NebulousLabs/Sia/modules/renter"
 	"github.com/NebulousLabs/Sia/sia"
 	"github.com/NebulousLabs/Sia/sia/host"
 	"github.com/NebulousLabs/Sia/sia/hostdb"
@@ -21,6 +22,8 @@ type daemon struct {
 
 	styleDir    string
 	downloadDir string
+	cacheDir    string
+	cache       *rentercache.Renter
 
 	template *template.Template
 }
@@ -47,8 +50,14 @@ func createDaemon(config Config) (d *daemon, err error) {
 		err = fmt.Errorf("problem with walletFile: %v", err)
 		return
 	}
+	expandedCacheDir, err := homedir.Expand(config.Siad.DownloadCacheDirectory)
+	if err != nil {
+		err = fmt.Errorf("problem with downloadCacheDir: %v", err)
+		return
+	}
 
-	// Create downloads directory and host directory.
+	// Create downloads directory, host directory, and download cache
+	// directory.
 	err = os.MkdirAll(expandedDownloadDir, os.ModeDir|os.ModePerm)
 	if err != nil {
 		return
@@ -57,11 +66,16 @@ func createDaemon(config Config) (d *daemon, err error) {
 	if err != nil {
 		return
 	}
+	err = os.MkdirAll(expandedCacheDir, os.ModeDir|os.ModePerm)
+	if err != nil {
+		return
+	}
 
 	// Create and fill out the daemon object.
 	d = &daemon{
 		styleDir:    expandedStyleDir,
 		downloadDir: expandedDownloadDir,
+		cacheDir:    expandedCacheDir,
 	}
 
 	// mr is used to resolve conflicts between packages and variable names
@@ -83,6 +97,15 @@ func createDaemon(config Config) (d *daemon, err error) {
 	if err != nil {
 		return
 	}
+	// d.cache is a separate modules/renter Renter that holds only the
+	// download cache installed by SetDownloadCache. The Renter handed to the
+	// core below is a different type and does not consult this cache; only
+	// the HTTP cache handlers registered below use it.
+	d.cache = &rentercache.Renter{}
+	if err = d.cache.SetDownloadCache(expandedCacheDir, config.Siad.DownloadCacheSize); err != nil {
+		err = fmt.Errorf("problem creating download cache: %v", err)
+		return
+	}
 
 	siaconfig := sia.Config{
 		HostDir:     expandedHostDir,
@@ -104,6 +127,9 @@ func createDaemon(config Config) (d *daemon, err error) {
 		return
 	}
 
+	// Register the download-cache inspection/flush/resize endpoints.
+	d.registerCacheHandlers()
+
 	// Begin listening for requests on the api.
 	d.setUpHandlers(config.Siad.APIaddr)
 